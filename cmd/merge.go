@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lindell/multi-gitter/internal/github"
+	"github.com/lindell/multi-gitter/internal/pr"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge every pull request opened by a run whose CI has passed",
+		RunE:  mergeRun,
+	}
+	cmd.Flags().String("manifest", "multi-gitter-run.json", "path to the run manifest, a JSON file as written by pr.Manifest.Save")
+	cmd.Flags().String("token", os.Getenv("GITHUB_TOKEN"), "the GitHub personal access token")
+	cmd.Flags().String("base-url", "", "the base url of the GitHub API, used for GitHub Enterprise")
+	cmd.Flags().String("merge-method", "merge", "the merge method to use: merge, squash or rebase")
+	cmd.Flags().Bool("wait", false, "keep retrying pull requests whose CI is still pending instead of skipping them")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "give up waiting for pending pull requests after this duration, only used with --wait")
+	cmd.Flags().Bool("auto-merge", false, "enable GitHub auto-merge instead of merging immediately, for pull requests whose CI is still pending")
+	RootCmd.AddCommand(cmd)
+}
+
+func mergeRun(cmd *cobra.Command, args []string) error {
+	manifestPath, _ := cmd.Flags().GetString("manifest")
+	token, _ := cmd.Flags().GetString("token")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	mergeMethod, _ := cmd.Flags().GetString("merge-method")
+	wait, _ := cmd.Flags().GetBool("wait")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	autoMerge, _ := cmd.Flags().GetBool("auto-merge")
+
+	manifest, err := pr.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := github.New(github.Github{Token: token, BaseURL: baseURL})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pending := manifest.Records
+	deadline := time.Now().Add(timeout)
+	backoff := 5 * time.Second
+
+	for len(pending) > 0 {
+		var stillPending []pr.Record
+		for _, record := range pending {
+			ref, err := github.NewPullRequestRef(record.Repo, record.Number)
+			if err != nil {
+				return err
+			}
+
+			state, err := client.PullRequestStatus(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("could not fetch status for %s#%d: %w", record.Repo, record.Number, err)
+			}
+
+			switch {
+			case state.Merged, state.Closed:
+				fmt.Fprintf(cmd.OutOrStdout(), "%s#%d already %s\n", record.Repo, record.Number, mergeState(state))
+			case state.CIPass():
+				if err := client.MergePR(ctx, ref, mergeMethod); err != nil {
+					return fmt.Errorf("could not merge %s#%d: %w", record.Repo, record.Number, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s#%d merged\n", record.Repo, record.Number)
+			case state.CI == github.CIStateFailure:
+				fmt.Fprintf(cmd.OutOrStdout(), "%s#%d CI failed, skipped\n", record.Repo, record.Number)
+			case autoMerge && state.CI != github.CIStateFailure:
+				if err := client.EnableAutoMerge(ctx, ref); err != nil {
+					return fmt.Errorf("could not enable auto-merge for %s#%d: %w", record.Repo, record.Number, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s#%d auto-merge enabled\n", record.Repo, record.Number)
+			default:
+				stillPending = append(stillPending, record)
+			}
+		}
+
+		pending = stillPending
+		if len(pending) == 0 || !wait {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %d pull request(s) to become mergeable", len(pending))
+		}
+
+		time.Sleep(backoff)
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+
+	if len(pending) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d pull request(s) still pending, skipped\n", len(pending))
+	}
+	return nil
+}
+
+func mergeState(state github.PullRequestState) string {
+	if state.Merged {
+		return "merged"
+	}
+	return "closed"
+}
@@ -0,0 +1,10 @@
+// Package cmd contains the multi-gitter CLI commands
+package cmd
+
+import "github.com/spf13/cobra"
+
+// RootCmd is the entrypoint command that every multi-gitter subcommand is registered under
+var RootCmd = &cobra.Command{
+	Use:   "multi-gitter",
+	Short: "A tool for making changes to multiple git repositories",
+}
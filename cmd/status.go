@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lindell/multi-gitter/internal/github"
+	"github.com/lindell/multi-gitter/internal/pr"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print the CI and review status of the pull requests opened by a run",
+		RunE:  statusRun,
+	}
+	cmd.Flags().String("manifest", "multi-gitter-run.json", "path to the run manifest, a JSON file as written by pr.Manifest.Save")
+	cmd.Flags().String("token", os.Getenv("GITHUB_TOKEN"), "the GitHub personal access token")
+	cmd.Flags().String("base-url", "", "the base url of the GitHub API, used for GitHub Enterprise")
+	RootCmd.AddCommand(cmd)
+}
+
+func statusRun(cmd *cobra.Command, args []string) error {
+	manifestPath, _ := cmd.Flags().GetString("manifest")
+	token, _ := cmd.Flags().GetString("token")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+
+	manifest, err := pr.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := github.New(github.Github{Token: token, BaseURL: baseURL})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tPR\tCI\tREVIEW")
+	for _, record := range manifest.Records {
+		ref, err := github.NewPullRequestRef(record.Repo, record.Number)
+		if err != nil {
+			return err
+		}
+
+		state, err := client.PullRequestStatus(ctx, ref)
+		if err != nil {
+			fmt.Fprintf(w, "%s\t#%d\terror: %v\t\n", record.Repo, record.Number, err)
+			continue
+		}
+
+		ci := string(state.CI)
+		switch {
+		case state.Merged:
+			ci = "merged"
+		case state.Closed:
+			ci = "closed"
+		}
+
+		review := state.ReviewDecision
+		if review == "" {
+			review = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t#%d\t%s\t%s\n", record.Repo, record.Number, ci, review)
+	}
+	return w.Flush()
+}
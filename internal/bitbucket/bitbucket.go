@@ -0,0 +1,256 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// managedTag is appended to every pull request multi-gitter opens against Bitbucket.
+// Bitbucket Cloud has no concept of labels on pull requests, so a searchable tag
+// in the description is used instead.
+const managedTag = "<!-- multi-gitter:managed -->"
+
+// Bitbucket contains the configuration needed to talk to the Bitbucket Cloud API
+type Bitbucket struct {
+	BaseURL string // e.g. https://api.bitbucket.org/2.0/
+	Token   string // App password or access token
+
+	httpClient *http.Client
+}
+
+// verify that Bitbucket satisfies the Forge interface
+var _ domain.Forge = Bitbucket{}
+
+// New creates a new Bitbucket client
+func New(token, baseURL string) *Bitbucket {
+	return &Bitbucket{
+		BaseURL:    baseURL,
+		Token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type repository struct {
+	Slug  string `json:"full_name"`
+	Links struct {
+		Clone []struct {
+			Name string `json:"name"`
+			Href string `json:"href"`
+		} `json:"clone"`
+	} `json:"links"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+func (r repository) GetURL() string {
+	for _, c := range r.Links.Clone {
+		if c.Name == "ssh" {
+			return c.Href
+		}
+	}
+	return ""
+}
+
+func (r repository) GetBranch() string { return r.MainBranch.Name }
+
+type pullRequest struct {
+	workspace string
+	repoSlug  string
+	id        int
+}
+
+func (pr pullRequest) GetRepository() string { return pr.workspace + "/" + pr.repoSlug }
+func (pr pullRequest) GetNumber() int        { return pr.id }
+
+// GetRepositories fetches repositories from a Bitbucket workspace
+func (b Bitbucket) GetRepositories(ctx context.Context, workspace string) ([]domain.Repository, error) {
+	allRepos := []domain.Repository{}
+	next := fmt.Sprintf("repositories/%s?pagelen=100", workspace)
+	for next != "" {
+		var page struct {
+			Values []repository `json:"values"`
+			Next   string       `json:"next"`
+		}
+		if err := b.do(ctx, http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Values {
+			allRepos = append(allRepos, r)
+		}
+		next = page.Next
+	}
+	return allRepos, nil
+}
+
+// CreatePullRequest creates a pull request, tagging its description so it can later be found by ListOpenPRs
+func (b Bitbucket) CreatePullRequest(ctx context.Context, repo domain.Repository, newPR domain.NewPullRequest) (domain.PullRequest, error) {
+	r, ok := repo.(repository)
+	if !ok {
+		return nil, errors.New("the repository needs to originate from this package")
+	}
+	workspace, repoSlug := splitSlug(r.Slug)
+
+	var created struct {
+		ID int `json:"id"`
+	}
+	body := map[string]interface{}{
+		"title":       newPR.Title,
+		"description": newPR.Body + "\n\n" + managedTag,
+		"source":      map[string]interface{}{"branch": map[string]string{"name": newPR.Head}},
+		"destination": map[string]interface{}{"branch": map[string]string{"name": newPR.Base}},
+	}
+	if err := b.do(ctx, http.MethodPost, fmt.Sprintf("repositories/%s/%s/pullrequests", workspace, repoSlug), body, &created); err != nil {
+		return nil, fmt.Errorf("could not create pull request: %w", err)
+	}
+
+	createdPR := pullRequest{workspace: workspace, repoSlug: repoSlug, id: created.ID}
+	if len(newPR.Reviewers) > 0 {
+		if err := b.AddReviewers(ctx, createdPR, newPR.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdPR, nil
+}
+
+// AddReviewers sets the reviewers of an already created pull request
+func (b Bitbucket) AddReviewers(ctx context.Context, pr domain.PullRequest, reviewers []string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	accounts := make([]map[string]string, 0, len(reviewers))
+	for _, r := range reviewers {
+		accounts = append(accounts, map[string]string{"username": r})
+	}
+	body := map[string]interface{}{"reviewers": accounts}
+	if err := b.do(ctx, http.MethodPut, fmt.Sprintf("repositories/%s/%s/pullrequests/%d", p.workspace, p.repoSlug, p.id), body, nil); err != nil {
+		return fmt.Errorf("could not add reviewers to pull request: %w", err)
+	}
+	return nil
+}
+
+// ListOpenPRs lists every open pull request carrying the managedTag in its description,
+// across every repository in workspace. Bitbucket Cloud has no workspace-wide pull
+// request listing endpoint, so repositories are enumerated first and queried one at a time.
+func (b Bitbucket) ListOpenPRs(ctx context.Context, workspace string) ([]domain.PullRequest, error) {
+	repos, err := b.GetRepositories(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []domain.PullRequest
+	for _, repo := range repos {
+		r := repo.(repository)
+		repoWorkspace, repoSlug := splitSlug(r.Slug)
+
+		next := fmt.Sprintf("repositories/%s/%s/pullrequests?state=OPEN&pagelen=100", repoWorkspace, repoSlug)
+		for next != "" {
+			var page struct {
+				Values []struct {
+					ID          int    `json:"id"`
+					Description string `json:"description"`
+				} `json:"values"`
+				Next string `json:"next"`
+			}
+			if err := b.do(ctx, http.MethodGet, next, nil, &page); err != nil {
+				return nil, fmt.Errorf("could not list pull requests for %s/%s: %w", repoWorkspace, repoSlug, err)
+			}
+			for _, v := range page.Values {
+				if !bytes.Contains([]byte(v.Description), []byte(managedTag)) {
+					continue
+				}
+				prs = append(prs, pullRequest{workspace: repoWorkspace, repoSlug: repoSlug, id: v.ID})
+			}
+			next = page.Next
+		}
+	}
+	return prs, nil
+}
+
+// MergePR merges pr using the given merge strategy (merge_commit, squash or fast_forward)
+func (b Bitbucket) MergePR(ctx context.Context, pr domain.PullRequest, method string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	body := map[string]interface{}{"merge_strategy": method}
+	if err := b.do(ctx, http.MethodPost, fmt.Sprintf("repositories/%s/%s/pullrequests/%d/merge", p.workspace, p.repoSlug, p.id), body, nil); err != nil {
+		return fmt.Errorf("could not merge %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// ClosePR declines pr without merging it
+func (b Bitbucket) ClosePR(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	if err := b.do(ctx, http.MethodPost, fmt.Sprintf("repositories/%s/%s/pullrequests/%d/decline", p.workspace, p.repoSlug, p.id), nil, nil); err != nil {
+		return fmt.Errorf("could not close %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+func splitSlug(slug string) (workspace, repoSlug string) {
+	for i := 0; i < len(slug); i++ {
+		if slug[i] == '/' {
+			return slug[:i], slug[i+1:]
+		}
+	}
+	return slug, ""
+}
+
+// do performs an authenticated request against the Bitbucket API, JSON-encoding body (if any)
+// and decoding the response into out (if non-nil). path may be a full URL (as returned in
+// pagination "next" links) or a path relative to BaseURL.
+func (b Bitbucket) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var buf io.Reader
+	if body != nil {
+		bb := &bytes.Buffer{}
+		if err := json.NewEncoder(bb).Encode(body); err != nil {
+			return err
+		}
+		buf = bb
+	}
+
+	target := path
+	if len(path) < 4 || path[:4] != "http" {
+		target = b.BaseURL + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("bitbucket api returned %s: %s", resp.Status, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
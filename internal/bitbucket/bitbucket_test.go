@@ -0,0 +1,23 @@
+package bitbucket
+
+import "testing"
+
+func TestSplitSlug(t *testing.T) {
+	tests := []struct {
+		slug          string
+		wantWorkspace string
+		wantRepoSlug  string
+	}{
+		{"workspace/repo", "workspace", "repo"},
+		{"workspace/repo/extra", "workspace", "repo/extra"},
+		{"no-slash", "no-slash", ""},
+	}
+
+	for _, tt := range tests {
+		workspace, repoSlug := splitSlug(tt.slug)
+		if workspace != tt.wantWorkspace || repoSlug != tt.wantRepoSlug {
+			t.Errorf("splitSlug(%q) = (%q, %q), want (%q, %q)",
+				tt.slug, workspace, repoSlug, tt.wantWorkspace, tt.wantRepoSlug)
+		}
+	}
+}
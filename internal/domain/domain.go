@@ -0,0 +1,36 @@
+package domain
+
+import "context"
+
+// Repository represents a repository that can be cloned and pushed to
+type Repository interface {
+	GetURL() string
+	GetBranch() string
+}
+
+// NewPullRequest represents a pull request to be created
+type NewPullRequest struct {
+	Title     string
+	Body      string
+	Head      string
+	Base      string
+	Reviewers []string
+}
+
+// PullRequest identifies a pull request that was previously opened by multi-gitter
+type PullRequest interface {
+	GetRepository() string // owner/name, or the forge-equivalent slug
+	GetNumber() int
+}
+
+// Forge is implemented by every version control platform multi-gitter can target.
+// A Forge is responsible for discovering repositories and managing the pull requests
+// multi-gitter opens against them.
+type Forge interface {
+	GetRepositories(ctx context.Context, orgName string) ([]Repository, error)
+	CreatePullRequest(ctx context.Context, repo Repository, newPR NewPullRequest) (PullRequest, error)
+	AddReviewers(ctx context.Context, pr PullRequest, reviewers []string) error
+	ListOpenPRs(ctx context.Context, orgName string) ([]PullRequest, error)
+	MergePR(ctx context.Context, pr PullRequest, method string) error
+	ClosePR(ctx context.Context, pr PullRequest) error
+}
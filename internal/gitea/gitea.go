@@ -0,0 +1,239 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// managedLabel is attached to every pull request multi-gitter opens against a Gitea instance
+const managedLabel = "multi-gitter"
+
+// Gitea contains the configuration needed to talk to a Gitea instance
+type Gitea struct {
+	BaseURL string // e.g. https://try.gitea.io/api/v1/
+	Token   string // Personal access token
+
+	httpClient *http.Client
+}
+
+// verify that Gitea satisfies the Forge interface
+var _ domain.Forge = Gitea{}
+
+// New creates a new Gitea client
+func New(token, baseURL string) *Gitea {
+	return &Gitea{
+		BaseURL:    baseURL,
+		Token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type repository struct {
+	SSH           string `json:"ssh_url"`
+	Slug          string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+	owner         string
+	name          string
+}
+
+func (r repository) GetURL() string    { return r.SSH }
+func (r repository) GetBranch() string { return r.DefaultBranch }
+
+type pullRequest struct {
+	owner  string
+	name   string
+	number int
+}
+
+func (pr pullRequest) GetRepository() string { return pr.owner + "/" + pr.name }
+func (pr pullRequest) GetNumber() int        { return pr.number }
+
+// GetRepositories fetches repositories from a Gitea organization
+func (g Gitea) GetRepositories(ctx context.Context, orgName string) ([]domain.Repository, error) {
+	allRepos := []domain.Repository{}
+	for page := 1; ; page++ {
+		var rr []repository
+		path := fmt.Sprintf("orgs/%s/repos?page=%d&limit=50", orgName, page)
+		if err := g.do(ctx, http.MethodGet, path, nil, &rr); err != nil {
+			return nil, err
+		}
+		if len(rr) == 0 {
+			break
+		}
+		for _, r := range rr {
+			if r.Archived {
+				continue
+			}
+			r.owner, r.name = orgName, repoNameFromSlug(r.Slug)
+			allRepos = append(allRepos, r)
+		}
+	}
+	return allRepos, nil
+}
+
+func repoNameFromSlug(slug string) string {
+	for i := len(slug) - 1; i >= 0; i-- {
+		if slug[i] == '/' {
+			return slug[i+1:]
+		}
+	}
+	return slug
+}
+
+// CreatePullRequest creates a pull request and labels it so it can later be found by ListOpenPRs
+func (g Gitea) CreatePullRequest(ctx context.Context, repo domain.Repository, newPR domain.NewPullRequest) (domain.PullRequest, error) {
+	r, ok := repo.(repository)
+	if !ok {
+		return nil, errors.New("the repository needs to originate from this package")
+	}
+
+	var created struct {
+		Number int `json:"number"`
+	}
+	body := map[string]interface{}{
+		"title": newPR.Title,
+		"body":  newPR.Body,
+		"head":  newPR.Head,
+		"base":  newPR.Base,
+	}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls", r.owner, r.name), body, &created); err != nil {
+		return nil, fmt.Errorf("could not create pull request: %w", err)
+	}
+
+	labelBody := map[string]interface{}{"labels": []string{managedLabel}}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/issues/%d/labels", r.owner, r.name, created.Number), labelBody, nil); err != nil {
+		return nil, fmt.Errorf("could not label pull request: %w", err)
+	}
+
+	createdPR := pullRequest{owner: r.owner, name: r.name, number: created.Number}
+	if len(newPR.Reviewers) > 0 {
+		if err := g.AddReviewers(ctx, createdPR, newPR.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdPR, nil
+}
+
+// AddReviewers requests reviews from the given users
+func (g Gitea) AddReviewers(ctx context.Context, pr domain.PullRequest, reviewers []string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	body := map[string]interface{}{"reviewers": reviewers}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", p.owner, p.name, p.number), body, nil); err != nil {
+		return fmt.Errorf("could not add reviewers to pull request: %w", err)
+	}
+	return nil
+}
+
+// ListOpenPRs lists every open pull request in orgName carrying the managedLabel
+func (g Gitea) ListOpenPRs(ctx context.Context, orgName string) ([]domain.PullRequest, error) {
+	repos, err := g.GetRepositories(ctx, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []domain.PullRequest
+	for _, repo := range repos {
+		r := repo.(repository)
+		for page := 1; ; page++ {
+			var found []struct {
+				Number int `json:"number"`
+				Labels []struct {
+					Name string `json:"name"`
+				} `json:"labels"`
+			}
+			path := fmt.Sprintf("repos/%s/%s/pulls?state=open&page=%d&limit=50", r.owner, r.name, page)
+			if err := g.do(ctx, http.MethodGet, path, nil, &found); err != nil {
+				return nil, fmt.Errorf("could not list pull requests for %s/%s: %w", r.owner, r.name, err)
+			}
+			if len(found) == 0 {
+				break
+			}
+			for _, pr := range found {
+				for _, l := range pr.Labels {
+					if l.Name == managedLabel {
+						prs = append(prs, pullRequest{owner: r.owner, name: r.name, number: pr.Number})
+						break
+					}
+				}
+			}
+		}
+	}
+	return prs, nil
+}
+
+// MergePR merges pr using the given merge method (merge, squash or rebase)
+func (g Gitea) MergePR(ctx context.Context, pr domain.PullRequest, method string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	body := map[string]interface{}{"Do": method}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls/%d/merge", p.owner, p.name, p.number), body, nil); err != nil {
+		return fmt.Errorf("could not merge %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// ClosePR closes pr without merging it
+func (g Gitea) ClosePR(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	body := map[string]interface{}{"state": "closed"}
+	if err := g.do(ctx, http.MethodPatch, fmt.Sprintf("repos/%s/%s/pulls/%d", p.owner, p.name, p.number), body, nil); err != nil {
+		return fmt.Errorf("could not close %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// do performs an authenticated request against the Gitea API, JSON-encoding body (if any)
+// and decoding the response into out (if non-nil)
+func (g Gitea) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var buf io.Reader
+	if body != nil {
+		b := &bytes.Buffer{}
+		if err := json.NewEncoder(b).Encode(body); err != nil {
+			return err
+		}
+		buf = b
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+path, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+g.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gitea api returned %s: %s", resp.Status, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
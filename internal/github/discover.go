@@ -0,0 +1,216 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// maxConcurrentPages bounds how many repository listing pages are fetched in parallel
+// once the total page count is known from the first response's Link header.
+const maxConcurrentPages = 4
+
+// DiscoverOptions configures repository enumeration across every source multi-gitter
+// understands: organizations, user accounts, the authenticated user, a GitHub Search
+// query, and gists, plus explicit "owner/name" includes, narrowed by glob excludes.
+type DiscoverOptions struct {
+	Orgs          []string
+	Users         []string
+	Authenticated bool
+	SearchQuery   string
+	Gists         bool
+
+	Include []string // explicit "owner/name" repositories to fetch in addition to the above
+	Exclude []string // glob patterns matched against the normalized "owner/name"
+}
+
+// Discover enumerates repositories across every source configured in opt, deduplicating
+// repositories that are found through more than one source.
+func (g Github) Discover(ctx context.Context, opt DiscoverOptions) ([]domain.Repository, error) {
+	cache := newRepoCache(opt.Exclude)
+
+	g2, ctx := errgroup.WithContext(ctx)
+	for _, org := range opt.Orgs {
+		org := org
+		g2.Go(func() error { return g.discoverOrgRepos(ctx, org, cache) })
+	}
+	for _, user := range opt.Users {
+		user := user
+		g2.Go(func() error { return g.discoverUserRepos(ctx, user, cache) })
+	}
+	if opt.Authenticated {
+		g2.Go(func() error { return g.discoverAuthenticatedUserRepos(ctx, cache) })
+	}
+	if opt.SearchQuery != "" {
+		g2.Go(func() error { return g.discoverSearchRepos(ctx, opt.SearchQuery, cache) })
+	}
+	if opt.Gists {
+		g2.Go(func() error { return g.discoverGists(ctx, cache) })
+	}
+	for _, include := range opt.Include {
+		include := include
+		g2.Go(func() error { return g.discoverExplicitRepo(ctx, include, cache) })
+	}
+
+	if err := g2.Wait(); err != nil {
+		return nil, err
+	}
+
+	return cache.repositories(), nil
+}
+
+// paginate fetches page 1 of a listing, then uses its Link header to learn the total
+// page count and fetches the remaining pages concurrently, calling add for each
+// repository (page order is not preserved, but repoCache is unordered regardless).
+func paginate(ctx context.Context, fetch func(ctx context.Context, page int) ([]*github.Repository, *github.Response, error), add func(*github.Repository)) error {
+	first, resp, err := fetch(ctx, 1)
+	if err != nil {
+		reposErrored.Inc()
+		return err
+	}
+	for _, r := range first {
+		add(r)
+	}
+
+	if resp.LastPage == 0 {
+		return nil
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentPages)
+	for page := 2; page <= resp.LastPage; page++ {
+		page := page
+		g.Go(func() error {
+			repos, _, err := fetch(ctx, page)
+			if err != nil {
+				reposErrored.Inc()
+				return err
+			}
+			for _, r := range repos {
+				add(r)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func (g Github) discoverOrgRepos(ctx context.Context, org string, cache *repoCache) error {
+	return paginate(ctx, func(ctx context.Context, page int) ([]*github.Repository, *github.Response, error) {
+		return g.client.Repositories.ListByOrg(ctx, org, &github.RepositoryListByOrgOptions{
+			ListOptions: github.ListOptions{Page: page, PerPage: 100},
+		})
+	}, func(r *github.Repository) {
+		if !r.GetArchived() && !r.GetDisabled() {
+			repo := repositoryFromGithub(r)
+			cache.add(repo.Slug, repo)
+		}
+	})
+}
+
+func (g Github) discoverUserRepos(ctx context.Context, user string, cache *repoCache) error {
+	return paginate(ctx, func(ctx context.Context, page int) ([]*github.Repository, *github.Response, error) {
+		return g.client.Repositories.ListByUser(ctx, user, &github.RepositoryListByUserOptions{
+			ListOptions: github.ListOptions{Page: page, PerPage: 100},
+		})
+	}, func(r *github.Repository) {
+		if !r.GetArchived() && !r.GetDisabled() {
+			repo := repositoryFromGithub(r)
+			cache.add(repo.Slug, repo)
+		}
+	})
+}
+
+func (g Github) discoverAuthenticatedUserRepos(ctx context.Context, cache *repoCache) error {
+	return paginate(ctx, func(ctx context.Context, page int) ([]*github.Repository, *github.Response, error) {
+		return g.client.Repositories.List(ctx, "", &github.RepositoryListOptions{
+			Affiliation: "owner,collaborator,organization_member",
+			ListOptions: github.ListOptions{Page: page, PerPage: 100},
+		})
+	}, func(r *github.Repository) {
+		if !r.GetArchived() && !r.GetDisabled() {
+			repo := repositoryFromGithub(r)
+			cache.add(repo.Slug, repo)
+		}
+	})
+}
+
+// discoverSearchRepos enumerates repositories matching a GitHub Search API query,
+// e.g. "language:go org:foo"
+func (g Github) discoverSearchRepos(ctx context.Context, query string, cache *repoCache) error {
+	opt := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := g.client.Search.Repositories(ctx, query, opt)
+		if err != nil {
+			reposErrored.Inc()
+			return fmt.Errorf("repository search %q failed: %w", query, err)
+		}
+		for _, r := range result.Repositories {
+			if !r.GetArchived() && !r.GetDisabled() {
+				repo := repositoryFromGithub(r)
+				cache.add(repo.Slug, repo)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return nil
+}
+
+// discoverGists treats the authenticated user's gists as clonable repositories
+func (g Github) discoverGists(ctx context.Context, cache *repoCache) error {
+	opt := &github.GistListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		gists, resp, err := g.client.Gists.List(ctx, "", opt)
+		if err != nil {
+			reposErrored.Inc()
+			return fmt.Errorf("could not list gists: %w", err)
+		}
+		for _, gist := range gists {
+			repo := repository{
+				SSH:           gist.GetGitPullURL(),
+				Slug:          "gists/" + gist.GetID(),
+				DefaultBranch: "master",
+			}
+			cache.add(repo.Slug, repo)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return nil
+}
+
+// discoverExplicitRepo fetches a single repository explicitly named via --repo owner/name
+func (g Github) discoverExplicitRepo(ctx context.Context, slug string, cache *repoCache) error {
+	owner, name, ok := splitSlug(slug)
+	if !ok {
+		return fmt.Errorf("%q is not a valid owner/name repository reference", slug)
+	}
+
+	r, _, err := g.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		reposErrored.Inc()
+		return fmt.Errorf("could not fetch repository %s: %w", slug, err)
+	}
+
+	repo := repositoryFromGithub(r)
+	cache.add(repo.Slug, repo)
+	return nil
+}
+
+func splitSlug(slug string) (owner, name string, ok bool) {
+	for i := 0; i < len(slug); i++ {
+		if slug[i] == '/' {
+			return slug[:i], slug[i+1:], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,25 @@
+package github
+
+import "testing"
+
+func TestSplitSlug(t *testing.T) {
+	tests := []struct {
+		slug      string
+		wantOwner string
+		wantName  string
+		wantOK    bool
+	}{
+		{"owner/name", "owner", "name", true},
+		{"owner/name/extra", "owner", "name/extra", true},
+		{"no-slash", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		owner, name, ok := splitSlug(tt.slug)
+		if owner != tt.wantOwner || name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("splitSlug(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.slug, owner, name, ok, tt.wantOwner, tt.wantName, tt.wantOK)
+		}
+	}
+}
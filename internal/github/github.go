@@ -1,20 +1,40 @@
 package github
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/shurcooL/githubv4"
+	"golang.org/x/oauth2"
 
 	"github.com/lindell/multi-gitter/internal/domain"
+	"github.com/lindell/multi-gitter/internal/github/transport"
 )
 
+// managedLabel is added to every pull request multi-gitter opens so that runs
+// of this tool can later find and act on their own pull requests.
+const managedLabel = "multi-gitter"
+
 // Github contain github configuration
 type Github struct {
 	BaseURL string
 	Token   string // Personal access token
+
+	// MaxRetries bounds how many times a secondary-rate-limited request is retried.
+	// Zero uses transport's default.
+	MaxRetries int
+	// MinRateBudget is the remaining primary rate limit below which requests are
+	// paused until the rate limit resets. Zero uses transport's default.
+	MinRateBudget int
+	// CacheDir, if set, caches GET responses on disk so unchanged requests (repository
+	// and pull request listings in particular) cost zero rate limit budget.
+	CacheDir string
+
+	client    *github.Client
+	gqlClient *githubv4.Client
 }
 
 // DefaultConfig contains values for the github.com api
@@ -23,29 +43,49 @@ var DefaultConfig = Github{
 	BaseURL: "https://api.github.com/",
 }
 
-type createPrRequest struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
-	Head  string `json:"head"`
-	Base  string `json:"base"`
-}
+// verify that Github satisfies the Forge interface
+var _ domain.Forge = Github{}
+
+// New creates a new Github client from cfg, which must at least set Token, and BaseURL
+// if targeting a GitHub Enterprise instance rather than github.com
+func New(cfg Github) (*Github, error) {
+	tc := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.Token}))
+	tc.Transport = transport.New(tc.Transport, transport.Options{
+		MaxRetries:    cfg.MaxRetries,
+		MinRateBudget: cfg.MinRateBudget,
+		CacheDir:      cfg.CacheDir,
+	})
+
+	client := github.NewClient(tc)
+	gqlClient := githubv4.NewClient(tc)
+	if cfg.BaseURL != "" {
+		var err error
+		client, err = client.WithEnterpriseURLs(cfg.BaseURL, cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not configure github base url: %w", err)
+		}
+		gqlClient = githubv4.NewEnterpriseClient(enterpriseGraphQLURL(cfg.BaseURL), tc)
+	}
 
-type pr struct {
-	ID     int `json:"id"`
-	Number int `json:"number"`
+	cfg.client = client
+	cfg.gqlClient = gqlClient
+	return &cfg, nil
 }
 
-type addReviewersRequest struct {
-	Reviewers []string `json:"reviewers"`
+// enterpriseGraphQLURL derives a GitHub Enterprise instance's GraphQL endpoint from its
+// REST base URL, e.g. "https://ghe.example.com/api/v3/" -> "https://ghe.example.com/api/graphql"
+func enterpriseGraphQLURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/api/v3")
+	return baseURL + "/api/graphql"
 }
 
 type repository struct {
-	SSH           string `json:"ssh_url"`
-	Slug          string `json:"full_name"`
-	DefaultBranch string `json:"default_branch"`
-
-	Archived bool `json:"archived"`
-	Disabled bool `json:"disabled"`
+	SSH           string
+	Slug          string
+	DefaultBranch string
+	owner         string
+	name          string
 }
 
 func (r repository) GetURL() string {
@@ -56,131 +96,334 @@ func (r repository) GetBranch() string {
 	return r.DefaultBranch
 }
 
-// GetRepositories fetches repositories from and organization
-func (g Github) GetRepositories(orgName string) ([]domain.Repository, error) {
+func repositoryFromGithub(r *github.Repository) repository {
+	return repository{
+		SSH:           r.GetSSHURL(),
+		Slug:          r.GetFullName(),
+		DefaultBranch: r.GetDefaultBranch(),
+		owner:         r.GetOwner().GetLogin(),
+		name:          r.GetName(),
+	}
+}
+
+// pullRequest identifies a pull request previously opened by multi-gitter
+type pullRequest struct {
+	owner  string
+	name   string
+	number int
+}
+
+func (pr pullRequest) GetRepository() string {
+	return pr.owner + "/" + pr.name
+}
+
+func (pr pullRequest) GetNumber() int {
+	return pr.number
+}
+
+// NewPullRequestRef reconstructs a domain.PullRequest for a repository/number pair
+// previously recorded in a run manifest
+func NewPullRequestRef(repoSlug string, number int) (domain.PullRequest, error) {
+	owner, name, ok := splitSlug(repoSlug)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid owner/name repository reference", repoSlug)
+	}
+	return pullRequest{owner: owner, name: name, number: number}, nil
+}
+
+// GetRepositories fetches repositories from an organization
+func (g Github) GetRepositories(ctx context.Context, orgName string) ([]domain.Repository, error) {
 	allRepos := []domain.Repository{}
-	for i := 1; ; i++ {
-		repos, err := g.getRepositories(orgName, i)
+	opt := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		repos, resp, err := g.client.Repositories.ListByOrg(ctx, orgName, opt)
 		if err != nil {
 			return nil, err
-		} else if len(repos) == 0 {
+		}
+
+		for _, r := range repos {
+			if !r.GetArchived() && !r.GetDisabled() {
+				allRepos = append(allRepos, repositoryFromGithub(r))
+			}
+		}
+
+		if resp.NextPage == 0 {
 			break
 		}
-		allRepos = append(allRepos, repos...)
+		opt.Page = resp.NextPage
 	}
+
 	return allRepos, nil
 }
 
-func (g Github) getRepositories(orgName string, page int) ([]domain.Repository, error) {
-	q := url.Values{
-		"page":     []string{fmt.Sprint(page)},
-		"per_page": []string{"100"},
+// CreatePullRequest creates a pull request, labeled so it can later be found by ListOpenPRs
+func (g Github) CreatePullRequest(ctx context.Context, repo domain.Repository, newPR domain.NewPullRequest) (domain.PullRequest, error) {
+	r, ok := repo.(repository)
+	if !ok {
+		return nil, errors.New("the repository needs to originate from this package")
 	}
 
-	url := fmt.Sprintf("%sorgs/%s/repos?"+q.Encode(), g.BaseURL, orgName)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	pr, _, err := g.client.PullRequests.Create(ctx, r.owner, r.name, &github.NewPullRequest{
+		Title: &newPR.Title,
+		Body:  &newPR.Body,
+		Head:  &newPR.Head,
+		Base:  &newPR.Base,
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not create pull request: %w", err)
 	}
-	req.Header.Add("Authorization", "token "+g.Token)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	if _, _, err := g.client.Issues.AddLabelsToIssue(ctx, r.owner, r.name, pr.GetNumber(), []string{managedLabel}); err != nil {
+		return nil, fmt.Errorf("could not label pull request: %w", err)
+	}
+
+	createdPR := pullRequest{owner: r.owner, name: r.name, number: pr.GetNumber()}
+
+	if len(newPR.Reviewers) > 0 {
+		if err := g.AddReviewers(ctx, createdPR, newPR.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdPR, nil
+}
+
+// AddReviewers requests reviews from the given users on an already created pull request
+func (g Github) AddReviewers(ctx context.Context, pr domain.PullRequest, reviewers []string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	if _, _, err := g.client.PullRequests.RequestReviewers(ctx, p.owner, p.name, p.number, github.ReviewersRequest{
+		Reviewers: reviewers,
+	}); err != nil {
+		return fmt.Errorf("could not add reviewers to pull request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, responseToError(resp, "cloud not fetching repositories")
+	return nil
+}
+
+// ListOpenPRs lists every open pull request in orgName carrying the managedLabel
+func (g Github) ListOpenPRs(ctx context.Context, orgName string) ([]domain.PullRequest, error) {
+	return g.ListPRsByLabel(ctx, orgName, managedLabel)
+}
+
+// ListPRsByLabel lists every open pull request in orgName carrying label
+func (g Github) ListPRsByLabel(ctx context.Context, orgName string, label string) ([]domain.PullRequest, error) {
+	var query struct {
+		Search struct {
+			Nodes []struct {
+				PullRequest struct {
+					Number     githubv4.Int
+					Repository struct {
+						Name  githubv4.String
+						Owner struct {
+							Login githubv4.String
+						}
+					}
+				} `graphql:"... on PullRequest"`
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   githubv4.String
+			}
+		} `graphql:"search(query: $searchQuery, type: ISSUE, first: 100, after: $cursor)"`
 	}
 
-	var rr []repository
-	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
-		return nil, err
+	searchQuery := githubv4.String(fmt.Sprintf("org:%s is:pr is:open label:%s", orgName, label))
+	variables := map[string]interface{}{
+		"searchQuery": searchQuery,
+		"cursor":      (*githubv4.String)(nil),
 	}
 
-	// Transform the slice of repositories struct into a slice of the interface repositories
-	repos := make([]domain.Repository, 0, len(rr))
-	for _, r := range rr {
-		if !r.Archived && !r.Disabled {
-			repos = append(repos, r)
+	var prs []domain.PullRequest
+	for {
+		if err := g.gqlClient.Query(ctx, &query, variables); err != nil {
+			return nil, fmt.Errorf("could not list pull requests labeled %s: %w", label, err)
+		}
+
+		for _, n := range query.Search.Nodes {
+			prs = append(prs, pullRequest{
+				owner:  string(n.PullRequest.Repository.Owner.Login),
+				name:   string(n.PullRequest.Repository.Name),
+				number: int(n.PullRequest.Number),
+			})
+		}
+
+		if !query.Search.PageInfo.HasNextPage {
+			break
 		}
+		variables["cursor"] = githubv4.NewString(query.Search.PageInfo.EndCursor)
 	}
-	return repos, nil
+
+	return prs, nil
 }
 
-// CreatePullRequest creates a pull request
-func (g Github) CreatePullRequest(repo domain.Repository, newPR domain.NewPullRequest) error {
-	repository, ok := repo.(repository)
+// PullRequestState describes the merge, review and CI state of a pull request
+type PullRequestState struct {
+	Merged         bool
+	Closed         bool
+	CI             CIState
+	ReviewDecision string // APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED, or empty if not configured
+}
+
+// CIPass reports whether CI has finished successfully. It returns false for both a
+// pending and a failed check run; callers that need to distinguish the two should
+// inspect CI directly.
+func (s PullRequestState) CIPass() bool {
+	return s.CI == CIStateSuccess
+}
+
+// PullRequestStatus fetches the current merge, review and CI state of a managed pull request
+func (g Github) PullRequestStatus(ctx context.Context, pr domain.PullRequest) (PullRequestState, error) {
+	p, ok := pr.(pullRequest)
 	if !ok {
-		return errors.New("the repository needs to originate from this package")
+		return PullRequestState{}, errors.New("the pull request needs to originate from this package")
 	}
 
-	pr, err := g.createPullRequest(repository, newPR)
-	if err != nil {
-		return err
+	var query struct {
+		Repository struct {
+			PullRequest struct {
+				Merged         bool
+				Closed         bool
+				ReviewDecision githubv4.String
+				Commits        struct {
+					Nodes []struct {
+						Commit struct {
+							StatusCheckRollup struct {
+								State githubv4.String
+							}
+						}
+					}
+				} `graphql:"commits(last: 1)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
 	}
 
-	if err := g.addReviewers(repository, newPR, pr); err != nil {
-		return err
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(p.owner),
+		"name":   githubv4.String(p.name),
+		"number": githubv4.Int(p.number),
+	}
+	if err := g.gqlClient.Query(ctx, &query, variables); err != nil {
+		return PullRequestState{}, fmt.Errorf("could not fetch pull request status: %w", err)
 	}
 
-	return nil
+	// A pull request with no commits nodes, or whose last commit has no status check
+	// rollup (e.g. CI not configured for the repository), is treated as passing,
+	// matching GitHub's own merge button behavior of not blocking a merge on checks
+	// that were never required.
+	ci := CIStateSuccess
+	if nodes := query.Repository.PullRequest.Commits.Nodes; len(nodes) > 0 {
+		if state := string(nodes[0].Commit.StatusCheckRollup.State); state != "" {
+			ci = ciStateFromRollup(state)
+		}
+	}
+
+	return PullRequestState{
+		Merged:         query.Repository.PullRequest.Merged,
+		Closed:         query.Repository.PullRequest.Closed,
+		CI:             ci,
+		ReviewDecision: string(query.Repository.PullRequest.ReviewDecision),
+	}, nil
 }
 
-func (g Github) createPullRequest(repo repository, newPR domain.NewPullRequest) (pr, error) {
-	buf := &bytes.Buffer{}
-	_ = json.NewEncoder(buf).Encode(createPrRequest{
-		Title: newPR.Title,
-		Body:  newPR.Body,
-		Head:  newPR.Head,
-		Base:  newPR.Base,
-	})
+// ciStateFromRollup maps a GraphQL StatusCheckRollup state to a CIState, treating
+// anything other than a clean success or a definite failure as still pending.
+func ciStateFromRollup(rollupState string) CIState {
+	switch rollupState {
+	case "SUCCESS":
+		return CIStateSuccess
+	case "FAILURE", "ERROR":
+		return CIStateFailure
+	default:
+		return CIStatePending
+	}
+}
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%srepos/%s/pulls", g.BaseURL, repo.Slug), buf)
-	if err != nil {
-		return pr{}, err
+// MergePR merges pr using the given merge method (merge, squash or rebase)
+func (g Github) MergePR(ctx context.Context, pr domain.PullRequest, method string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
 	}
-	req.Header.Add("Authorization", "token "+g.Token)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return pr{}, err
+	if _, _, err := g.client.PullRequests.Merge(ctx, p.owner, p.name, p.number, "", &github.PullRequestOptions{
+		MergeMethod: method,
+	}); err != nil {
+		return fmt.Errorf("could not merge %s: %w", pr.GetRepository(), err)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return pr{}, responseToError(resp, "could not create pull request")
+// ClosePR closes pr without merging it
+func (g Github) ClosePR(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
 	}
 
-	var pullRequest pr
-	if err := json.NewDecoder(resp.Body).Decode(&pullRequest); err != nil {
-		return pr{}, err
+	closed := "closed"
+	if _, _, err := g.client.PullRequests.Edit(ctx, p.owner, p.name, p.number, &github.PullRequest{
+		State: &closed,
+	}); err != nil {
+		return fmt.Errorf("could not close %s: %w", pr.GetRepository(), err)
 	}
-	return pullRequest, nil
+	return nil
 }
 
-func (g Github) addReviewers(repo repository, newPR domain.NewPullRequest, createdPR pr) error {
-	buf := &bytes.Buffer{}
-	_ = json.NewEncoder(buf).Encode(addReviewersRequest{
-		Reviewers: newPR.Reviewers,
-	})
+// CIState describes the combined status of every check run against a commit
+type CIState string
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%srepos/%s/pulls/%d/requested_reviewers", g.BaseURL, repo.Slug, createdPR.Number), buf)
-	if err != nil {
-		return err
+// The possible CIState values, mirroring the GitHub combined status states
+const (
+	CIStateSuccess CIState = "success"
+	CIStatePending CIState = "pending"
+	CIStateFailure CIState = "failure"
+)
+
+// GetCombinedStatus fetches the combined CI status of headSHA in repoSlug (owner/name)
+func (g Github) GetCombinedStatus(ctx context.Context, repoSlug string, headSHA string) (CIState, error) {
+	owner, name, ok := splitSlug(repoSlug)
+	if !ok {
+		return "", fmt.Errorf("%q is not a valid owner/name repository reference", repoSlug)
 	}
-	req.Header.Add("Authorization", "token "+g.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	status, _, err := g.client.Repositories.GetCombinedStatus(ctx, owner, name, headSHA, nil)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("could not fetch combined status for %s@%s: %w", repoSlug, headSHA, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return responseToError(resp, "could not add reviewers to pull request")
+	return ciStateFromRollup(strings.ToUpper(status.GetState())), nil
+}
+
+// EnableAutoMerge turns on GitHub's auto-merge for pr, so it merges itself as soon as
+// required checks and reviews pass
+func (g Github) EnableAutoMerge(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
 	}
 
+	nodeID, _, err := g.client.PullRequests.Get(ctx, p.owner, p.name, p.number)
+	if err != nil {
+		return fmt.Errorf("could not fetch pull request %s: %w", pr.GetRepository(), err)
+	}
+
+	var mutation struct {
+		EnablePullRequestAutoMerge struct {
+			ClientMutationID githubv4.String
+		} `graphql:"enablePullRequestAutoMerge(input: $input)"`
+	}
+	input := githubv4.EnablePullRequestAutoMergeInput{
+		PullRequestID: nodeID.GetNodeID(),
+	}
+	if err := g.gqlClient.Mutate(ctx, &mutation, input, nil); err != nil {
+		return fmt.Errorf("could not enable auto-merge for %s: %w", pr.GetRepository(), err)
+	}
 	return nil
 }
@@ -0,0 +1,24 @@
+package github
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics exposed about repository enumeration, so a run's discovery phase can be
+// observed the same way its clone/push phase is.
+var (
+	reposDiscovered = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "multi_gitter_repos_discovered_total",
+		Help: "The total number of repositories discovered across all enumeration sources",
+	})
+	reposFiltered = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "multi_gitter_repos_filtered_total",
+		Help: "The total number of repositories excluded by exclude filters",
+	})
+	reposErrored = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "multi_gitter_repos_errored_total",
+		Help: "The total number of repository enumeration requests (org, user, search query, gist or repository fetches) that returned an error",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(reposDiscovered, reposFiltered, reposErrored)
+}
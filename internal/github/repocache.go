@@ -0,0 +1,71 @@
+package github
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// repoCache deduplicates repositories discovered through multiple enumeration sources
+// (an org listing, a user listing, and an explicit --repo flag might all surface the
+// same repository) and applies the exclude filter configured for a run. Explicit
+// --repo includes are additive: they are fetched and added alongside whatever org/user/
+// search/gist enumeration finds, not used to restrict those other sources.
+type repoCache struct {
+	mu      sync.Mutex
+	seen    map[string]domain.Repository
+	exclude []string
+}
+
+// newRepoCache creates a repoCache. exclude is a list of glob patterns matched against
+// the normalized owner/name.
+func newRepoCache(exclude []string) *repoCache {
+	return &repoCache{
+		seen:    map[string]domain.Repository{},
+		exclude: exclude,
+	}
+}
+
+// normalizeRepoKey lowercases an owner/name slug and strips a trailing ".git", so that
+// "Foo/Bar.git", "foo/bar" and "FOO/BAR" all dedupe to the same cache entry.
+func normalizeRepoKey(slug string) string {
+	slug = strings.ToLower(slug)
+	slug = strings.TrimSuffix(slug, ".git")
+	return slug
+}
+
+// add inserts a repository into the cache, keyed by slug, unless it is excluded or
+// already present. It returns true if the repository was newly added.
+func (rc *repoCache) add(slug string, repo domain.Repository) bool {
+	key := normalizeRepoKey(slug)
+
+	for _, pattern := range rc.exclude {
+		if ok, _ := path.Match(pattern, key); ok {
+			reposFiltered.Inc()
+			return false
+		}
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.seen[key]; ok {
+		return false
+	}
+	rc.seen[key] = repo
+	reposDiscovered.Inc()
+	return true
+}
+
+// repositories returns every repository currently held by the cache
+func (rc *repoCache) repositories() []domain.Repository {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	repos := make([]domain.Repository, 0, len(rc.seen))
+	for _, r := range rc.seen {
+		repos = append(repos, r)
+	}
+	return repos
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+func TestRepoCacheDedupesCaseAndDotGit(t *testing.T) {
+	rc := newRepoCache(nil)
+
+	if added := rc.add("Foo/Bar", repository{Slug: "Foo/Bar"}); !added {
+		t.Fatalf("expected first add of Foo/Bar to succeed")
+	}
+	if added := rc.add("foo/bar.git", repository{Slug: "foo/bar.git"}); added {
+		t.Fatalf("expected foo/bar.git to be treated as a duplicate of Foo/Bar")
+	}
+
+	repos := rc.repositories()
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repository after dedup, got %d", len(repos))
+	}
+}
+
+func TestRepoCacheExclude(t *testing.T) {
+	rc := newRepoCache([]string{"foo/*"})
+
+	if added := rc.add("foo/bar", repository{Slug: "foo/bar"}); added {
+		t.Fatalf("expected foo/bar to be filtered by the foo/* exclude pattern")
+	}
+	if added := rc.add("baz/qux", repository{Slug: "baz/qux"}); !added {
+		t.Fatalf("expected baz/qux to be added since it doesn't match the exclude pattern")
+	}
+
+	repos := rc.repositories()
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repository, got %d", len(repos))
+	}
+}
+
+func TestRepoCacheDoesNotRestrictToIncludes(t *testing.T) {
+	// Includes are additive, not an exclusive whitelist: a repository discovered
+	// through org/user enumeration must be kept even though it was never passed
+	// explicitly via --repo.
+	rc := newRepoCache(nil)
+
+	if added := rc.add("org/discovered", repository{Slug: "org/discovered"}); !added {
+		t.Fatalf("expected org/discovered to be added")
+	}
+
+	repos := rc.repositories()
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repository, got %d", len(repos))
+	}
+	if repos[0].(repository).Slug != "org/discovered" {
+		t.Fatalf("unexpected repository kept: %+v", repos[0])
+	}
+}
+
+var _ domain.Repository = repository{}
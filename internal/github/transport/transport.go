@@ -0,0 +1,285 @@
+// Package transport provides an http.RoundTripper that makes calling the GitHub REST
+// and GraphQL APIs resilient: it waits out the primary rate limit before it is
+// exhausted, backs off on secondary (abuse detection) rate limits, and serves
+// conditional GET requests from an on-disk ETag cache so unchanged responses cost
+// zero rate budget.
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMinRateBudget is used when Options.MinRateBudget is left at zero
+const defaultMinRateBudget = 50
+
+// defaultMaxRetries is used when Options.MaxRetries is left at zero
+const defaultMaxRetries = 5
+
+// Options configures a Transport
+type Options struct {
+	// MaxRetries bounds how many times a secondary-rate-limited request is retried
+	MaxRetries int
+	// MinRateBudget is the number of remaining primary rate limit requests below which
+	// the transport sleeps until the rate limit resets, rather than risk a 403
+	MinRateBudget int
+	// CacheDir, if set, stores GET responses on disk keyed by URL, together with their
+	// ETag/Last-Modified, so unchanged responses can be re-validated with a conditional request
+	CacheDir string
+}
+
+// Transport wraps base, applying rate-limit awareness, secondary rate limit backoff,
+// and ETag caching to every request
+type Transport struct {
+	base http.RoundTripper
+	opt  Options
+
+	mu         sync.Mutex
+	sleepUntil time.Time // primary rate limit reset to wait out before the next request
+}
+
+// New creates a Transport. base is the underlying RoundTripper (typically an
+// oauth2-authenticating transport); if nil, http.DefaultTransport is used.
+func New(base http.RoundTripper, opt Options) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opt.MinRateBudget == 0 {
+		opt.MinRateBudget = defaultMinRateBudget
+	}
+	if opt.MaxRetries == 0 {
+		opt.MaxRetries = defaultMaxRetries
+	}
+	return &Transport{base: base, opt: opt}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet && t.opt.CacheDir != "" {
+		return t.roundTripCached(req)
+	}
+	return t.roundTripRateLimited(req)
+}
+
+// roundTripRateLimited performs req, waiting out any primary rate limit reset recorded by
+// a previous response before issuing it, and retrying with exponential backoff and jitter
+// on secondary (abuse) rate limits
+func (t *Transport) roundTripRateLimited(req *http.Request) (*http.Response, error) {
+	t.waitForRateLimitReset()
+
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; attempt <= t.opt.MaxRetries; attempt++ {
+		resp, err = t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		// The primary rate limit is recorded rather than waited out here: the
+		// response already succeeded, so the wait is deferred to the next request
+		// that actually needs to go out instead of stalling this one's caller.
+		if remaining, reset, ok := parseRateLimit(resp.Header); ok && remaining < t.opt.MinRateBudget {
+			t.mu.Lock()
+			t.sleepUntil = reset
+			t.mu.Unlock()
+		}
+
+		if !isSecondaryRateLimit(resp) {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		if attempt == t.opt.MaxRetries {
+			break
+		}
+		if req.Body != nil {
+			if req.GetBody == nil {
+				// The request body was already consumed and can't be rewound for a
+				// retry; return the failed response rather than resend an empty body.
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		time.Sleep(backoffDuration(resp.Header, attempt))
+	}
+
+	return resp, nil
+}
+
+// roundTripCached serves req from the on-disk cache when the destination confirms
+// (via a 304) that the cached copy is still current, storing fresh responses for next time
+func (t *Transport) roundTripCached(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+	entry, _ := loadCacheEntry(t.opt.CacheDir, key)
+
+	if entry != nil {
+		// RoundTripper must not mutate the request it was given, so conditional
+		// headers are added to a clone rather than req itself.
+		cloned := req.Clone(req.Context())
+		if entry.ETag != "" {
+			cloned.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			cloned.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+		req = cloned
+	}
+
+	resp, err := t.roundTripRateLimited(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		return entry.toResponse(req), nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		newEntry := &cacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			StatusCode:   resp.StatusCode,
+			Header:       resp.Header,
+			Body:         body,
+		}
+		_ = saveCacheEntry(t.opt.CacheDir, key, newEntry)
+	}
+
+	return resp, nil
+}
+
+// waitForRateLimitReset blocks until any primary rate limit reset recorded by a previous
+// response has passed, then clears it so concurrent callers don't wait on it twice.
+func (t *Transport) waitForRateLimitReset() {
+	t.mu.Lock()
+	reset := t.sleepUntil
+	t.sleepUntil = time.Time{}
+	t.mu.Unlock()
+
+	if !reset.IsZero() {
+		sleepUntil(reset)
+	}
+}
+
+func parseRateLimit(h http.Header) (remaining int, reset time.Time, ok bool) {
+	r := h.Get("X-RateLimit-Remaining")
+	s := h.Get("X-RateLimit-Reset")
+	if r == "" || s == "" {
+		return 0, time.Time{}, false
+	}
+	remainingVal, err1 := strconv.Atoi(r)
+	resetVal, err2 := strconv.ParseInt(s, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, time.Time{}, false
+	}
+	return remainingVal, time.Unix(resetVal, 0), true
+}
+
+func sleepUntil(t time.Time) {
+	if d := time.Until(t); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// isSecondaryRateLimit reports whether resp is GitHub's secondary (abuse detection) rate
+// limit response: a 403 carrying a Retry-After header, or an abuse-detection message body
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		return true
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "abuse")
+}
+
+// backoffDuration computes the wait before the next retry: the Retry-After header if
+// present, otherwise exponential backoff with jitter, starting at one second
+func backoffDuration(h http.Header, attempt int) time.Duration {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+
+	base := time.Second << attempt
+	jitter := time.Duration(rand.Int63n(int64(base) / 2))
+	return base + jitter
+}
+
+// cacheEntry is the on-disk representation of a single cached response
+type cacheEntry struct {
+	ETag         string      `json:"etag"`
+	LastModified string      `json:"lastModified"`
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+func (e *cacheEntry) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode: e.StatusCode,
+		Status:     http.StatusText(e.StatusCode),
+		Header:     e.Header,
+		Body:       io.NopCloser(bytes.NewReader(e.Body)),
+		Request:    req,
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadCacheEntry(dir, key string) (*cacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func saveCacheEntry(dir, key string, e *cacheEntry) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, key+".json"), data, 0o644)
+}
@@ -0,0 +1,239 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// managedLabel is attached to every merge request multi-gitter opens against a GitLab instance
+const managedLabel = "multi-gitter"
+
+// Gitlab contains the configuration needed to talk to a GitLab instance
+type Gitlab struct {
+	BaseURL string // e.g. https://gitlab.com/api/v4/
+	Token   string // Personal access token
+
+	httpClient *http.Client
+}
+
+// verify that Gitlab satisfies the Forge interface
+var _ domain.Forge = Gitlab{}
+
+// New creates a new Gitlab client
+func New(token, baseURL string) *Gitlab {
+	return &Gitlab{
+		BaseURL:    baseURL,
+		Token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type repository struct {
+	ID            int    `json:"id"`
+	SSH           string `json:"ssh_url_to_repo"`
+	Slug          string `json:"path_with_namespace"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+}
+
+func (r repository) GetURL() string    { return r.SSH }
+func (r repository) GetBranch() string { return r.DefaultBranch }
+
+type pullRequest struct {
+	projectID int
+	slug      string
+	iid       int
+}
+
+func (pr pullRequest) GetRepository() string { return pr.slug }
+func (pr pullRequest) GetNumber() int        { return pr.iid }
+
+// GetRepositories fetches projects from a GitLab group
+func (g Gitlab) GetRepositories(ctx context.Context, groupName string) ([]domain.Repository, error) {
+	allRepos := []domain.Repository{}
+	for page := 1; ; page++ {
+		var rr []repository
+		path := fmt.Sprintf("groups/%s/projects?page=%d&per_page=100&include_subgroups=true", url.PathEscape(groupName), page)
+		if err := g.do(ctx, http.MethodGet, path, nil, &rr); err != nil {
+			return nil, err
+		}
+		if len(rr) == 0 {
+			break
+		}
+		for _, r := range rr {
+			if r.Archived {
+				continue
+			}
+			allRepos = append(allRepos, r)
+		}
+	}
+	return allRepos, nil
+}
+
+// CreatePullRequest creates a merge request and labels it so it can later be found by ListOpenPRs
+func (g Gitlab) CreatePullRequest(ctx context.Context, repo domain.Repository, newPR domain.NewPullRequest) (domain.PullRequest, error) {
+	r, ok := repo.(repository)
+	if !ok {
+		return nil, errors.New("the repository needs to originate from this package")
+	}
+
+	var created struct {
+		IID int `json:"iid"`
+	}
+	body := map[string]interface{}{
+		"title":         newPR.Title,
+		"description":   newPR.Body,
+		"source_branch": newPR.Head,
+		"target_branch": newPR.Base,
+		"labels":        managedLabel,
+	}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("projects/%d/merge_requests", r.ID), body, &created); err != nil {
+		return nil, fmt.Errorf("could not create merge request: %w", err)
+	}
+
+	createdPR := pullRequest{projectID: r.ID, slug: r.Slug, iid: created.IID}
+	if len(newPR.Reviewers) > 0 {
+		if err := g.AddReviewers(ctx, createdPR, newPR.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdPR, nil
+}
+
+// AddReviewers sets the reviewers of an already created merge request.
+// GitLab reviewers are tracked by numeric user id, so usernames are resolved first.
+func (g Gitlab) AddReviewers(ctx context.Context, pr domain.PullRequest, reviewers []string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	ids := make([]int, 0, len(reviewers))
+	for _, username := range reviewers {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := g.do(ctx, http.MethodGet, "users?username="+url.QueryEscape(username), nil, &users); err != nil {
+			return fmt.Errorf("could not resolve reviewer %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("could not resolve reviewer %s: no such user", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+
+	body := map[string]interface{}{"reviewer_ids": ids}
+	if err := g.do(ctx, http.MethodPut, fmt.Sprintf("projects/%d/merge_requests/%d", p.projectID, p.iid), body, nil); err != nil {
+		return fmt.Errorf("could not add reviewers to merge request: %w", err)
+	}
+	return nil
+}
+
+// ListOpenPRs lists every open merge request in groupName carrying the managedLabel
+func (g Gitlab) ListOpenPRs(ctx context.Context, groupName string) ([]domain.PullRequest, error) {
+	var prs []domain.PullRequest
+	for page := 1; ; page++ {
+		var found []struct {
+			IID        int `json:"iid"`
+			ProjectID  int `json:"project_id"`
+			References struct {
+				Full string `json:"full"` // e.g. "group/project!123"
+			} `json:"references"`
+		}
+		path := fmt.Sprintf("groups/%s/merge_requests?state=opened&labels=%s&page=%d&per_page=100",
+			url.PathEscape(groupName), managedLabel, page)
+		if err := g.do(ctx, http.MethodGet, path, nil, &found); err != nil {
+			return nil, fmt.Errorf("could not list merge requests: %w", err)
+		}
+		if len(found) == 0 {
+			break
+		}
+		for _, mr := range found {
+			prs = append(prs, pullRequest{projectID: mr.ProjectID, iid: mr.IID, slug: projectPathFromReference(mr.References.Full)})
+		}
+	}
+	return prs, nil
+}
+
+// projectPathFromReference extracts the "group/project" path from a GitLab merge
+// request full reference (e.g. "group/project!123"), so pullRequest.slug stays
+// consistent with repository.Slug (path_with_namespace) rather than a web URL.
+func projectPathFromReference(full string) string {
+	if i := strings.LastIndex(full, "!"); i != -1 {
+		return full[:i]
+	}
+	return full
+}
+
+// MergePR merges pr. GitLab's merge method is a project-level setting, so method is ignored.
+func (g Gitlab) MergePR(ctx context.Context, pr domain.PullRequest, method string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	if err := g.do(ctx, http.MethodPut, fmt.Sprintf("projects/%d/merge_requests/%d/merge", p.projectID, p.iid), nil, nil); err != nil {
+		return fmt.Errorf("could not merge %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// ClosePR closes pr without merging it
+func (g Gitlab) ClosePR(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	body := map[string]interface{}{"state_event": "close"}
+	if err := g.do(ctx, http.MethodPut, fmt.Sprintf("projects/%d/merge_requests/%d", p.projectID, p.iid), body, nil); err != nil {
+		return fmt.Errorf("could not close %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// do performs an authenticated request against the GitLab API, JSON-encoding body (if any)
+// and decoding the response into out (if non-nil)
+func (g Gitlab) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var buf io.Reader
+	if body != nil {
+		b := &bytes.Buffer{}
+		if err := json.NewEncoder(b).Encode(body); err != nil {
+			return err
+		}
+		buf = b
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+path, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gitlab api returned %s: %s", resp.Status, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// checkpoint records transfer progress so a migration that crashes or is interrupted
+// can resume without re-creating entities that already exist on the destination forge.
+type checkpoint struct {
+	path string
+
+	// Done maps a stage name (e.g. "issues") to the set of source ids already transferred
+	// during that stage.
+	Done map[string]map[string]bool `json:"done"`
+	// Translation is persisted alongside Done so cross-reference rewriting survives a resume.
+	Translation map[string]string `json:"translation"`
+}
+
+// loadCheckpoint reads a checkpoint from path, returning a fresh one if the file does not exist
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{
+		path:        path,
+		Done:        map[string]map[string]bool{},
+		Translation: map[string]string{},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	c.path = path
+	return c, nil
+}
+
+func (c *checkpoint) isDone(stage, sourceID string) bool {
+	return c.Done[stage][sourceID]
+}
+
+func (c *checkpoint) markDone(stage, sourceID string) error {
+	if c.Done[stage] == nil {
+		c.Done[stage] = map[string]bool{}
+	}
+	c.Done[stage][sourceID] = true
+	return c.save()
+}
+
+// save persists the checkpoint to disk, or does nothing if no CheckpointPath was
+// configured (in which case progress only lives in memory for the current run).
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
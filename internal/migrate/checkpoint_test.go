@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint: %v", err)
+	}
+	if cp.isDone("issues", "1") {
+		t.Fatalf("expected a fresh checkpoint to have nothing done")
+	}
+
+	cp.Translation["1"] = "101"
+	if err := cp.markDone("issues", "1"); err != nil {
+		t.Fatalf("markDone: %v", err)
+	}
+
+	resumed, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint (resume): %v", err)
+	}
+	if !resumed.isDone("issues", "1") {
+		t.Fatalf("expected resumed checkpoint to remember issue 1 as done")
+	}
+	if resumed.isDone("issues", "2") {
+		t.Fatalf("expected resumed checkpoint to not mark untouched issue 2 as done")
+	}
+	if resumed.Translation["1"] != "101" {
+		t.Fatalf("expected resumed checkpoint to restore the translation table, got %q", resumed.Translation["1"])
+	}
+}
+
+func TestLoadCheckpointMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint: %v", err)
+	}
+	if cp.isDone("labels", "1") {
+		t.Fatalf("expected a new checkpoint to have nothing done")
+	}
+}
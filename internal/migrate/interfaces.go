@@ -0,0 +1,56 @@
+package migrate
+
+import "context"
+
+// Downloader streams the content of a single repository from a source forge, in the
+// stable order the Migrator transfers it in: repo metadata, milestones, labels, issues,
+// issue comments, pull requests, pull request comments, releases, release assets.
+type Downloader interface {
+	RepositoryMeta(ctx context.Context) (RepositoryMeta, error)
+	Milestones(ctx context.Context) ([]Milestone, error)
+	Labels(ctx context.Context) ([]Label, error)
+	Issues(ctx context.Context) ([]Issue, error)
+	IssueComments(ctx context.Context, issue Issue) ([]Comment, error)
+	PullRequests(ctx context.Context) ([]PullRequest, error)
+	PullRequestComments(ctx context.Context, pr PullRequest) ([]Comment, error)
+	Releases(ctx context.Context) ([]Release, error)
+}
+
+// Uploader recreates a repository's content on a destination forge. CreateMilestone,
+// CreateLabel, CreateIssue and CreatePullRequest return the id the destination forge
+// assigned; the Migrator records the ids from CreateIssue and CreatePullRequest in the
+// TranslationTable so later cross-references (e.g. "fixes #12") can be rewritten.
+type Uploader interface {
+	CreateRepository(ctx context.Context, meta RepositoryMeta) error
+	CreateMilestone(ctx context.Context, m Milestone) (destID string, err error)
+	CreateLabel(ctx context.Context, l Label) (destID string, err error)
+	CreateIssue(ctx context.Context, i Issue) (destID string, err error)
+	CreateIssueComment(ctx context.Context, issueDestID string, c Comment) error
+	CreatePullRequest(ctx context.Context, pr PullRequest) (destID string, err error)
+	CreatePullRequestComment(ctx context.Context, prDestID string, c Comment) error
+	CreateRelease(ctx context.Context, r Release) error
+}
+
+// TranslationTable maps a source entity id (as recorded in Issue.SourceID or
+// PullRequest.SourceID) to the id assigned to it on
+// the destination forge, so comment bodies referencing "#12" or similar can be rewritten
+// to point at the new issue/pull request.
+type TranslationTable struct {
+	entries map[string]string
+}
+
+// NewTranslationTable creates an empty TranslationTable
+func NewTranslationTable() *TranslationTable {
+	return &TranslationTable{entries: map[string]string{}}
+}
+
+// Set records that sourceID now lives at destID on the destination forge
+func (t *TranslationTable) Set(sourceID, destID string) {
+	t.entries[sourceID] = destID
+}
+
+// Lookup returns the destination id for sourceID, and whether it has been recorded yet
+func (t *TranslationTable) Lookup(sourceID string) (string, bool) {
+	destID, ok := t.entries[sourceID]
+	return destID, ok
+}
@@ -0,0 +1,299 @@
+// Package migrate copies a repository's milestones, labels, issues, pull requests and
+// releases from one forge to another, reusing the repository enumeration each forge
+// backend already provides.
+package migrate
+
+import (
+	"context"
+	"fmt"
+)
+
+// Options configures a single repository transfer
+type Options struct {
+	// CheckpointPath, if set, persists transfer progress so a later run can resume
+	// instead of re-creating entities that already exist on the destination.
+	CheckpointPath string
+	// DryRun, when true, only reports what would be transferred; Uploader is never called.
+	DryRun bool
+	// Logf receives a line of human-readable progress for every entity transferred or
+	// (in dry-run mode) that would be transferred. It may be nil.
+	Logf func(format string, args ...interface{})
+}
+
+// Migrator transfers a single repository's content from a Downloader to an Uploader
+type Migrator struct {
+	opt Options
+}
+
+// New creates a Migrator configured with opt
+func New(opt Options) *Migrator {
+	if opt.Logf == nil {
+		opt.Logf = func(string, ...interface{}) {}
+	}
+	return &Migrator{opt: opt}
+}
+
+// Run transfers one repository's content from src to dst in a stable order: repository
+// metadata, then milestones, labels, issues, issue comments, pull requests, pull request
+// comments, releases. Cross-references in comment bodies (e.g. "fixes #12") are rewritten
+// using the ids CreateIssue/CreatePullRequest assigned on the destination.
+func (m *Migrator) Run(ctx context.Context, src Downloader, dst Uploader) error {
+	cp, err := m.loadOrInit()
+	if err != nil {
+		return err
+	}
+	translation := translationFromCheckpoint(cp)
+
+	meta, err := src.RepositoryMeta(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch repository metadata: %w", err)
+	}
+	m.opt.Logf("repository: %s", meta.Name)
+	if !m.opt.DryRun {
+		if err := dst.CreateRepository(ctx, meta); err != nil {
+			return fmt.Errorf("could not create repository: %w", err)
+		}
+	}
+
+	if err := m.transferMilestones(ctx, cp, translation, src, dst); err != nil {
+		return err
+	}
+	if err := m.transferLabels(ctx, cp, translation, src, dst); err != nil {
+		return err
+	}
+	issueIDs, err := m.transferIssues(ctx, cp, translation, src, dst)
+	if err != nil {
+		return err
+	}
+	if err := m.transferIssueComments(ctx, cp, translation, src, dst, issueIDs); err != nil {
+		return err
+	}
+	prIDs, err := m.transferPullRequests(ctx, cp, translation, src, dst)
+	if err != nil {
+		return err
+	}
+	if err := m.transferPullRequestComments(ctx, cp, translation, src, dst, prIDs); err != nil {
+		return err
+	}
+	if err := m.transferReleases(ctx, cp, src, dst); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Migrator) loadOrInit() (*checkpoint, error) {
+	if m.opt.CheckpointPath == "" {
+		return &checkpoint{Done: map[string]map[string]bool{}, Translation: map[string]string{}}, nil
+	}
+	return loadCheckpoint(m.opt.CheckpointPath)
+}
+
+func translationFromCheckpoint(cp *checkpoint) *TranslationTable {
+	t := NewTranslationTable()
+	for sourceID, destID := range cp.Translation {
+		t.Set(sourceID, destID)
+	}
+	return t
+}
+
+func (m *Migrator) transferMilestones(ctx context.Context, cp *checkpoint, _ *TranslationTable, src Downloader, dst Uploader) error {
+	milestones, err := src.Milestones(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch milestones: %w", err)
+	}
+	for _, ms := range milestones {
+		if cp.isDone("milestones", ms.SourceID) {
+			continue
+		}
+		m.opt.Logf("milestone: %s", ms.Title)
+		if m.opt.DryRun {
+			continue
+		}
+		if _, err := dst.CreateMilestone(ctx, ms); err != nil {
+			return fmt.Errorf("could not create milestone %q: %w", ms.Title, err)
+		}
+		if err := cp.markDone("milestones", ms.SourceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) transferLabels(ctx context.Context, cp *checkpoint, _ *TranslationTable, src Downloader, dst Uploader) error {
+	labels, err := src.Labels(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch labels: %w", err)
+	}
+	for _, l := range labels {
+		if cp.isDone("labels", l.SourceID) {
+			continue
+		}
+		m.opt.Logf("label: %s", l.Name)
+		if m.opt.DryRun {
+			continue
+		}
+		if _, err := dst.CreateLabel(ctx, l); err != nil {
+			return fmt.Errorf("could not create label %q: %w", l.Name, err)
+		}
+		if err := cp.markDone("labels", l.SourceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) transferIssues(ctx context.Context, cp *checkpoint, translation *TranslationTable, src Downloader, dst Uploader) (map[string]string, error) {
+	issues, err := src.Issues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch issues: %w", err)
+	}
+
+	destIDs := map[string]string{}
+	for _, issue := range issues {
+		if destID, ok := cp.Translation[issue.SourceID]; ok {
+			destIDs[issue.SourceID] = destID
+			continue
+		}
+
+		m.opt.Logf("issue: #%d %s", issue.Number, issue.Title)
+		issue.Body = rewriteReferences(issue.Body, translation)
+
+		if m.opt.DryRun {
+			continue
+		}
+
+		destID, err := dst.CreateIssue(ctx, issue)
+		if err != nil {
+			return nil, fmt.Errorf("could not create issue #%d: %w", issue.Number, err)
+		}
+
+		translation.Set(issue.SourceID, destID)
+		cp.Translation[issue.SourceID] = destID
+		destIDs[issue.SourceID] = destID
+		if err := cp.markDone("issues", issue.SourceID); err != nil {
+			return nil, err
+		}
+	}
+	return destIDs, nil
+}
+
+func (m *Migrator) transferIssueComments(ctx context.Context, cp *checkpoint, translation *TranslationTable, src Downloader, dst Uploader, issueIDs map[string]string) error {
+	issues, err := src.Issues(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch issues: %w", err)
+	}
+
+	for _, issue := range issues {
+		comments, err := src.IssueComments(ctx, issue)
+		if err != nil {
+			return fmt.Errorf("could not fetch comments for issue #%d: %w", issue.Number, err)
+		}
+		for _, c := range comments {
+			if cp.isDone("issue_comments", c.SourceID) {
+				continue
+			}
+			m.opt.Logf("issue comment: #%d by %s", issue.Number, c.Author)
+			c.Body = rewriteReferences(c.Body, translation)
+			if m.opt.DryRun {
+				continue
+			}
+			if err := dst.CreateIssueComment(ctx, issueIDs[issue.SourceID], c); err != nil {
+				return fmt.Errorf("could not create comment on issue #%d: %w", issue.Number, err)
+			}
+			if err := cp.markDone("issue_comments", c.SourceID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) transferPullRequests(ctx context.Context, cp *checkpoint, translation *TranslationTable, src Downloader, dst Uploader) (map[string]string, error) {
+	prs, err := src.PullRequests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch pull requests: %w", err)
+	}
+
+	destIDs := map[string]string{}
+	for _, p := range prs {
+		if destID, ok := cp.Translation[p.SourceID]; ok {
+			destIDs[p.SourceID] = destID
+			continue
+		}
+
+		m.opt.Logf("pull request: #%d %s", p.Number, p.Title)
+		p.Body = rewriteReferences(p.Body, translation)
+
+		if m.opt.DryRun {
+			continue
+		}
+
+		destID, err := dst.CreatePullRequest(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("could not create pull request #%d: %w", p.Number, err)
+		}
+
+		translation.Set(p.SourceID, destID)
+		cp.Translation[p.SourceID] = destID
+		destIDs[p.SourceID] = destID
+		if err := cp.markDone("pulls", p.SourceID); err != nil {
+			return nil, err
+		}
+	}
+	return destIDs, nil
+}
+
+func (m *Migrator) transferPullRequestComments(ctx context.Context, cp *checkpoint, translation *TranslationTable, src Downloader, dst Uploader, prIDs map[string]string) error {
+	prs, err := src.PullRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch pull requests: %w", err)
+	}
+
+	for _, p := range prs {
+		comments, err := src.PullRequestComments(ctx, p)
+		if err != nil {
+			return fmt.Errorf("could not fetch comments for pull request #%d: %w", p.Number, err)
+		}
+		for _, c := range comments {
+			if cp.isDone("pull_comments", c.SourceID) {
+				continue
+			}
+			m.opt.Logf("pull request comment: #%d by %s", p.Number, c.Author)
+			c.Body = rewriteReferences(c.Body, translation)
+			if m.opt.DryRun {
+				continue
+			}
+			if err := dst.CreatePullRequestComment(ctx, prIDs[p.SourceID], c); err != nil {
+				return fmt.Errorf("could not create comment on pull request #%d: %w", p.Number, err)
+			}
+			if err := cp.markDone("pull_comments", c.SourceID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) transferReleases(ctx context.Context, cp *checkpoint, src Downloader, dst Uploader) error {
+	releases, err := src.Releases(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch releases: %w", err)
+	}
+	for _, r := range releases {
+		if cp.isDone("releases", r.SourceID) {
+			continue
+		}
+		m.opt.Logf("release: %s (%d assets)", r.Tag, len(r.Assets))
+		if m.opt.DryRun {
+			continue
+		}
+		if err := dst.CreateRelease(ctx, r); err != nil {
+			return fmt.Errorf("could not create release %q: %w", r.Tag, err)
+		}
+		if err := cp.markDone("releases", r.SourceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
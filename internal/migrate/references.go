@@ -0,0 +1,21 @@
+package migrate
+
+import "regexp"
+
+// referencePattern matches "#123" style cross-references to issues and pull requests
+var referencePattern = regexp.MustCompile(`#(\d+)`)
+
+// rewriteReferences rewrites every "#123" cross-reference in body whose source id is
+// known to the translation table, pointing it at the number assigned on the destination
+// forge instead. References the table has no entry for (because the entity has not been
+// transferred, or belongs to a different repository) are left untouched.
+func rewriteReferences(body string, translation *TranslationTable) string {
+	return referencePattern.ReplaceAllStringFunc(body, func(match string) string {
+		sourceID := match[1:]
+		destID, ok := translation.Lookup(sourceID)
+		if !ok {
+			return match
+		}
+		return "#" + destID
+	})
+}
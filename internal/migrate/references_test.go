@@ -0,0 +1,23 @@
+package migrate
+
+import "testing"
+
+func TestRewriteReferencesKnownIDs(t *testing.T) {
+	translation := NewTranslationTable()
+	translation.Set("12", "34")
+
+	got := rewriteReferences("fixes #12 and relates to #99", translation)
+	want := "fixes #34 and relates to #99"
+	if got != want {
+		t.Errorf("rewriteReferences() = %q, want %q", got, want)
+	}
+}
+
+func TestRewriteReferencesNoTranslation(t *testing.T) {
+	translation := NewTranslationTable()
+
+	body := "see #1 for context"
+	if got := rewriteReferences(body, translation); got != body {
+		t.Errorf("rewriteReferences() = %q, want unchanged %q", got, body)
+	}
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import "time"
+
+// RepositoryMeta describes the repository itself, transferred before any of its content
+type RepositoryMeta struct {
+	Name          string
+	Description   string
+	Private       bool
+	DefaultBranch string
+}
+
+// Milestone is a milestone transferred from the source repository
+type Milestone struct {
+	SourceID    string
+	Title       string
+	Description string
+	DueOn       *time.Time
+	Closed      bool
+}
+
+// Label is a label transferred from the source repository
+type Label struct {
+	SourceID string
+	Name     string
+	Color    string
+}
+
+// Comment is a comment on an Issue or PullRequest
+type Comment struct {
+	SourceID string
+	Author   string
+	Body     string
+	Created  time.Time
+}
+
+// Issue is an issue transferred from the source repository
+type Issue struct {
+	SourceID  string
+	Number    int
+	Title     string
+	Body      string
+	Author    string
+	Labels    []string // label names, resolved against already-created Label entities
+	Milestone string   // milestone title, resolved against already-created Milestone entities
+	Closed    bool
+	Created   time.Time
+}
+
+// PullRequest is a pull (or merge) request transferred from the source repository
+type PullRequest struct {
+	SourceID string
+	Number   int
+	Title    string
+	Body     string
+	Author   string
+	Head     string
+	Base     string
+	Merged   bool
+	Closed   bool
+	Created  time.Time
+}
+
+// ReleaseAsset is a single file attached to a Release
+type ReleaseAsset struct {
+	SourceID    string
+	Name        string
+	ContentType string
+	Download    func() ([]byte, error)
+}
+
+// Release is a release transferred from the source repository
+type Release struct {
+	SourceID string
+	Tag      string
+	Title    string
+	Body     string
+	Draft    bool
+	Assets   []ReleaseAsset
+}
@@ -0,0 +1,56 @@
+// Package pr persists the pull requests opened by a multi-gitter run, so that a later
+// invocation of the status and merge verbs can find and act on them.
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Record identifies a single pull request opened during a run
+type Record struct {
+	Repo    string `json:"repo"`     // owner/name
+	Number  int    `json:"number"`   // pull request number
+	HeadSHA string `json:"head_sha"` // the commit the pull request was opened with
+}
+
+// Manifest is the full set of pull requests opened during one multi-gitter run. It is
+// filled with Add and written with Save; the status and merge commands read it with
+// Load. None of the multi-gitter commands calls Add or Save.
+type Manifest struct {
+	RunID   string   `json:"runId"`
+	Records []Record `json:"records"`
+}
+
+// Load reads a manifest previously written by Save
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read run manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("could not parse run manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Add records a newly opened pull request
+func (m *Manifest) Add(repo string, number int, headSHA string) {
+	m.Records = append(m.Records, Record{
+		Repo:    repo,
+		Number:  number,
+		HeadSHA: headSHA,
+	})
+}
+
+// Save writes the manifest to path as JSON
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
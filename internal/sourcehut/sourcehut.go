@@ -0,0 +1,238 @@
+package sourcehut
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lindell/multi-gitter/internal/domain"
+)
+
+// managedLabel is recorded against every patchset multi-gitter submits, so later runs
+// can find and act on their own submissions.
+//
+// SourceHut has no native "pull request" concept; git.sr.ht instead tracks patchsets
+// submitted against a repository's mailing list. CreatePullRequest maps onto submitting
+// a patchset, and a PullRequest here identifies a patchset.
+const managedLabel = "multi-gitter"
+
+// Sourcehut contains the configuration needed to talk to the git.sr.ht GraphQL API
+type Sourcehut struct {
+	BaseURL string // e.g. https://git.sr.ht/query
+	Token   string // Personal access token
+
+	httpClient *http.Client
+}
+
+// verify that Sourcehut satisfies the Forge interface
+var _ domain.Forge = Sourcehut{}
+
+// New creates a new Sourcehut client
+func New(token, baseURL string) *Sourcehut {
+	return &Sourcehut{
+		BaseURL:    baseURL,
+		Token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type repository struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Owner         string
+	SSH           string `json:"-"`
+	DefaultBranch string
+}
+
+func (r repository) GetURL() string    { return r.SSH }
+func (r repository) GetBranch() string { return r.DefaultBranch }
+
+type pullRequest struct {
+	owner      string
+	repoName   string
+	patchsetID int
+}
+
+func (pr pullRequest) GetRepository() string { return pr.owner + "/" + pr.repoName }
+func (pr pullRequest) GetNumber() int        { return pr.patchsetID }
+
+// GetRepositories fetches repositories owned by ownerName
+func (s Sourcehut) GetRepositories(ctx context.Context, ownerName string) ([]domain.Repository, error) {
+	var result struct {
+		User struct {
+			Repositories struct {
+				Results []struct {
+					ID   int    `json:"id"`
+					Name string `json:"name"`
+				} `json:"results"`
+			} `json:"repositories"`
+		} `json:"user"`
+	}
+
+	query := `query($name: String!) { user(username: $name) { repositories { results { id name } } } }`
+	if err := s.query(ctx, query, map[string]interface{}{"name": ownerName}, &result); err != nil {
+		return nil, err
+	}
+
+	allRepos := make([]domain.Repository, 0, len(result.User.Repositories.Results))
+	for _, r := range result.User.Repositories.Results {
+		allRepos = append(allRepos, repository{
+			ID:            r.ID,
+			Name:          r.Name,
+			Owner:         ownerName,
+			SSH:           fmt.Sprintf("git@git.sr.ht:~%s/%s", ownerName, r.Name),
+			DefaultBranch: "master",
+		})
+	}
+	return allRepos, nil
+}
+
+// CreatePullRequest submits a patchset for repo, labeled so it can later be found by ListOpenPRs
+func (s Sourcehut) CreatePullRequest(ctx context.Context, repo domain.Repository, newPR domain.NewPullRequest) (domain.PullRequest, error) {
+	r, ok := repo.(repository)
+	if !ok {
+		return nil, errors.New("the repository needs to originate from this package")
+	}
+
+	var result struct {
+		SubmitPatchset struct {
+			ID int `json:"id"`
+		} `json:"submitPatchset"`
+	}
+	query := `mutation($repoId: Int!, $subject: String!, $body: String!, $label: String!) {
+		submitPatchset(repoId: $repoId, subject: $subject, body: $body, labels: [$label]) { id }
+	}`
+	variables := map[string]interface{}{
+		"repoId":  r.ID,
+		"subject": newPR.Title,
+		"body":    newPR.Body,
+		"label":   managedLabel,
+	}
+	if err := s.query(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("could not submit patchset: %w", err)
+	}
+
+	return pullRequest{owner: r.Owner, repoName: r.Name, patchsetID: result.SubmitPatchset.ID}, nil
+}
+
+// AddReviewers has no SourceHut equivalent: reviews happen as mailing list replies,
+// not as an assignment on the patchset. It is a no-op kept to satisfy the Forge interface.
+func (s Sourcehut) AddReviewers(ctx context.Context, pr domain.PullRequest, reviewers []string) error {
+	return nil
+}
+
+// ListOpenPRs lists every unresolved patchset for ownerName carrying the managedLabel
+func (s Sourcehut) ListOpenPRs(ctx context.Context, ownerName string) ([]domain.PullRequest, error) {
+	var result struct {
+		User struct {
+			Patchsets struct {
+				Results []struct {
+					ID         int    `json:"id"`
+					Status     string `json:"status"`
+					Repository struct {
+						Name string `json:"name"`
+					} `json:"repository"`
+					Labels []string `json:"labels"`
+				} `json:"results"`
+			} `json:"patchsets"`
+		} `json:"user"`
+	}
+
+	query := `query($name: String!) { user(username: $name) { patchsets { results { id status repository { name } labels } } } }`
+	if err := s.query(ctx, query, map[string]interface{}{"name": ownerName}, &result); err != nil {
+		return nil, fmt.Errorf("could not list patchsets: %w", err)
+	}
+
+	var prs []domain.PullRequest
+	for _, p := range result.User.Patchsets.Results {
+		if p.Status != "UNRESOLVED" {
+			continue
+		}
+		for _, l := range p.Labels {
+			if l == managedLabel {
+				prs = append(prs, pullRequest{owner: ownerName, repoName: p.Repository.Name, patchsetID: p.ID})
+				break
+			}
+		}
+	}
+	return prs, nil
+}
+
+// MergePR marks pr as applied. method is ignored; SourceHut patchsets are always applied
+// with `git am`, so there is no separate merge strategy to choose.
+func (s Sourcehut) MergePR(ctx context.Context, pr domain.PullRequest, method string) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	query := `mutation($id: Int!) { updatePatchsetStatus(id: $id, status: APPLIED) { id } }`
+	if err := s.query(ctx, query, map[string]interface{}{"id": p.patchsetID}, nil); err != nil {
+		return fmt.Errorf("could not mark %s as applied: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// ClosePR marks pr as rejected
+func (s Sourcehut) ClosePR(ctx context.Context, pr domain.PullRequest) error {
+	p, ok := pr.(pullRequest)
+	if !ok {
+		return errors.New("the pull request needs to originate from this package")
+	}
+
+	query := `mutation($id: Int!) { updatePatchsetStatus(id: $id, status: REJECTED) { id } }`
+	if err := s.query(ctx, query, map[string]interface{}{"id": p.patchsetID}, nil); err != nil {
+		return fmt.Errorf("could not close %s: %w", pr.GetRepository(), err)
+	}
+	return nil
+}
+
+// query executes a GraphQL request against the git.sr.ht API and decodes the "data" field into out
+func (s Sourcehut) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("sourcehut api returned %s: %s", resp.Status, data)
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		return errors.New(envelope.Errors[0].Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}